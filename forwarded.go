@@ -0,0 +1,118 @@
+package realip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ForwardedElement holds the parsed parameters of a single element of a
+// Forwarded header, as defined by RFC 7239.
+type ForwardedElement struct {
+	For   string
+	By    string
+	Host  string
+	Proto string
+}
+
+// ParseForwarded parses the value of a Forwarded header (RFC 7239) into its
+// comma-separated elements, each holding the raw value of its "for", "by",
+// "host" and "proto" parameters. Quoted values are unquoted; unknown
+// parameters are ignored.
+func ParseForwarded(headerValue string) []ForwardedElement {
+	var elements []ForwardedElement
+
+	for _, part := range strings.Split(headerValue, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var el ForwardedElement
+		for _, pair := range strings.Split(part, ";") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			key := strings.ToLower(strings.TrimSpace(kv[0]))
+			value := unquote(strings.TrimSpace(kv[1]))
+
+			switch key {
+			case "for":
+				el.For = value
+			case "by":
+				el.By = value
+			case "host":
+				el.Host = value
+			case "proto":
+				el.Proto = value
+			}
+		}
+
+		elements = append(elements, el)
+	}
+
+	return elements
+}
+
+// unquote strips the surrounding quotes from a quoted-string value, as used
+// by forwarded-pair values that contain characters not allowed in a bare
+// token (e.g. "[2001:db8::1]:8080").
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+		s = strings.ReplaceAll(s, `\"`, `"`)
+		s = strings.ReplaceAll(s, `\\`, `\`)
+	}
+	return s
+}
+
+// forwardedAddresses returns the candidate client addresses carried by the
+// request's Forwarded header (RFC 7239), in the same left-to-right hop
+// order as X-Forwarded-For, dropping elements whose "for" parameter is
+// missing or obfuscated.
+func forwardedAddresses(r *http.Request) []string {
+	elements := ParseForwarded(headerValues(r, "Forwarded"))
+	addresses := make([]string, 0, len(elements))
+	for _, el := range elements {
+		if addr := ipFromForwardedFor(el.For); addr != "" {
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
+// ipFromForwardedFor extracts the IP address from the raw value of a
+// Forwarded header's "for" parameter, which may be an obfuscated identifier
+// ("unknown", "_hidden"), a bare IPv4/IPv6 address, or either with a
+// trailing ":port" (IPv6 addresses bracketed per RFC 7239, e.g.
+// "[2001:db8::1]:8080"). Returns "" if the value does not carry a usable IP.
+func ipFromForwardedFor(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "unknown" || strings.HasPrefix(raw, "_") {
+		return ""
+	}
+
+	if strings.HasPrefix(raw, "[") {
+		end := strings.IndexByte(raw, ']')
+		if end == -1 {
+			return ""
+		}
+		raw = raw[1:end]
+	} else if strings.Count(raw, ":") <= 1 {
+		// A bare IPv6 address without brackets has more than one colon and
+		// no port to strip; anything else may carry a trailing ":port".
+		raw = getIPfromHostPort(raw)
+	}
+
+	if net.ParseIP(raw) == nil {
+		return ""
+	}
+	return raw
+}
@@ -0,0 +1,87 @@
+package realip
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseForwarded(t *testing.T) {
+	testData := []struct {
+		name     string
+		header   string
+		expected []ForwardedElement
+	}{
+		{
+			name:   "Simple",
+			header: `for=192.0.2.60;proto=http;by=203.0.113.43`,
+			expected: []ForwardedElement{
+				{For: "192.0.2.60", Proto: "http", By: "203.0.113.43"},
+			},
+		}, {
+			name:   "Quoted IPv6 with port",
+			header: `for="[2001:db8::1]:8080"`,
+			expected: []ForwardedElement{
+				{For: "[2001:db8::1]:8080"},
+			},
+		}, {
+			name:   "Multiple elements",
+			header: `for=192.0.2.60, for=198.51.100.17`,
+			expected: []ForwardedElement{
+				{For: "192.0.2.60"},
+				{For: "198.51.100.17"},
+			},
+		}, {
+			name:   "Obfuscated and unknown identifiers",
+			header: `for=unknown, for=_hidden, for=192.0.2.60`,
+			expected: []ForwardedElement{
+				{For: "unknown"},
+				{For: "_hidden"},
+				{For: "192.0.2.60"},
+			},
+		},
+	}
+
+	for _, v := range testData {
+		actual := ParseForwarded(v.header)
+		if !reflect.DeepEqual(actual, v.expected) {
+			t.Errorf("%s: expected %+v but got %+v", v.name, v.expected, actual)
+		}
+	}
+}
+
+func TestClientIPFromRequestForwarded(t *testing.T) {
+	testData := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "Plain for",
+			header:   `for=192.0.2.60;proto=http;by=203.0.113.43`,
+			expected: "192.0.2.60",
+		}, {
+			name:     "Quoted IPv6 for with port",
+			header:   `for="[2001:db8::1]:8080"`,
+			expected: "2001:db8::1",
+		}, {
+			name:     "Skips unknown and private before public",
+			header:   `for=unknown, for=10.0.0.1, for=192.0.2.60`,
+			expected: "192.0.2.60",
+		},
+	}
+
+	for _, v := range testData {
+		headerList := http.Header{}
+		headerList.Set("Forwarded", v.header)
+		request := &http.Request{RemoteAddr: "", Header: headerList}
+
+		actual, source := ClientIPFromRequest(request)
+		if actual != v.expected {
+			t.Errorf("%s: expected:[%s], actual:[%s] from source:%s", v.name, v.expected, actual, source)
+		}
+		if actual != "" && source != "Forwarded" {
+			t.Errorf("%s: expected source Forwarded, got %s", v.name, source)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package realip
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// contextKey is the type of the key under which Middleware and Handler
+// store the resolved client IP in a request's context.
+type contextKey string
+
+// ClientIPContextKey is the context key Middleware and Handler use to store
+// the resolved client IP and its source header. The value stored under it
+// has an unexported type, so its IP and source are read with FromContext.
+const ClientIPContextKey contextKey = "realip-client-ip"
+
+// clientIPContext is the value stored under ClientIPContextKey.
+type clientIPContext struct {
+	ip     string
+	source string
+}
+
+// Middleware resolves the client IP for every request using a default
+// Resolver (empty trust list) and stores it in the request's context. It
+// also rewrites r.RemoteAddr to the resolved IP so downstream logging and
+// handlers that read RemoteAddr directly see the real client. Use Handler
+// instead to supply a Resolver configured with trusted proxies.
+func Middleware(next http.Handler) http.Handler {
+	return Handler(New(), next)
+}
+
+// Handler is like Middleware but resolves the client IP using res instead
+// of a default Resolver.
+func Handler(res *Resolver, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, source := res.ClientIP(r)
+
+		ctx := context.WithValue(r.Context(), ClientIPContextKey, clientIPContext{ip: ip, source: source})
+		r = r.WithContext(ctx)
+
+		if ip != "" {
+			r.RemoteAddr = net.JoinHostPort(ip, "0")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// FromContext returns the client IP and source header stored by Middleware
+// or Handler, and whether a value was present.
+func FromContext(ctx context.Context) (ip string, source string, ok bool) {
+	v, ok := ctx.Value(ClientIPContextKey).(clientIPContext)
+	if !ok {
+		return "", "", false
+	}
+
+	return v.ip, v.source, true
+}
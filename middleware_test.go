@@ -0,0 +1,49 @@
+package realip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerInjectsClientIP(t *testing.T) {
+	publicAddr := "144.12.54.87"
+	proxyAddr := "10.0.0.5"
+
+	var gotIP, gotSource string
+	var gotOK bool
+	var gotRemoteAddr string
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIP, gotSource, gotOK = FromContext(r.Context())
+		gotRemoteAddr = r.RemoteAddr
+	})
+
+	res := New(WithTrustedProxies("10.0.0.0/8"))
+	handler := Handler(res, next)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = proxyAddr + ":1234"
+	req.Header.Set("X-Forwarded-For", publicAddr+", "+proxyAddr)
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !gotOK {
+		t.Fatal("expected FromContext to find a value")
+	}
+	if gotIP != publicAddr {
+		t.Errorf("expected ip %s, got %s", publicAddr, gotIP)
+	}
+	if gotSource != "X-Forwarded-For" {
+		t.Errorf("expected source X-Forwarded-For, got %s", gotSource)
+	}
+	if gotRemoteAddr != publicAddr+":0" {
+		t.Errorf("expected RemoteAddr rewritten to %s:0, got %s", publicAddr, gotRemoteAddr)
+	}
+}
+
+func TestFromContextWithoutMiddleware(t *testing.T) {
+	if _, _, ok := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); ok {
+		t.Error("expected ok=false when Middleware was never run")
+	}
+}
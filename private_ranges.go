@@ -0,0 +1,63 @@
+package realip
+
+import "net"
+
+// PrivateRanges is a configurable set of CIDR blocks considered private.
+// isPrivateAddress and isValidPublicIP, which back the private-IP
+// heuristic used by ClientIPFromRequest and FromRequest, consult
+// DefaultPrivateRanges by default; callers can add to or replace it to
+// recognize additional networks (e.g. a corporate VPN range) as private
+// without patching the package.
+type PrivateRanges struct {
+	Blocks []*net.IPNet
+}
+
+// DefaultPrivateRanges is the PrivateRanges used by isPrivateAddress and
+// isValidPublicIP. Replace it, or append to its Blocks, to customize what
+// the package treats as private.
+var DefaultPrivateRanges = Default()
+
+// Default returns the built-in private/reserved ranges: RFC 1918 and IPv6
+// unique-local/link-local space, plus CGNAT (RFC 6598), the IETF protocol
+// assignments block, and the benchmarking range (RFC 2544).
+//
+// https://en.wikipedia.org/wiki/Private_network
+//
+// https://en.wikipedia.org/wiki/Link-local_address
+func Default() *PrivateRanges {
+	blocks := []string{
+		"127.0.0.1/8",    // localhost
+		"10.0.0.0/8",     // 24-bit block
+		"172.16.0.0/12",  // 20-bit block
+		"192.168.0.0/16", // 16-bit block
+		"169.254.0.0/16", // link local address
+		"100.64.0.0/10",  // CGNAT shared address space
+		"192.0.0.0/24",   // IETF protocol assignments
+		"198.18.0.0/15",  // benchmarking
+		"::1/128",        // localhost IPv6
+		"fc00::/7",       // unique local address IPv6
+		"fe80::/10",      // link local address IPv6
+	}
+
+	ranges := &PrivateRanges{Blocks: make([]*net.IPNet, len(blocks))}
+	for i, block := range blocks {
+		_, cidr, _ := net.ParseCIDR(block)
+		ranges.Blocks[i] = cidr
+	}
+
+	return ranges
+}
+
+// Contains reports whether ip falls inside any of the configured blocks.
+// net.IPNet.Contains normalizes IPv4-mapped IPv6 addresses (e.g.
+// "::ffff:10.0.0.1") against IPv4 blocks, so no separate unmapping is
+// needed here.
+func (p *PrivateRanges) Contains(ip net.IP) bool {
+	for _, block := range p.Blocks {
+		if block.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package realip
+
+import (
+	"net"
+	"testing"
+)
+
+func TestDefaultPrivateRangesContains(t *testing.T) {
+	testData := map[string]bool{
+		"100.64.0.1":      true, // CGNAT
+		"192.0.0.1":       true, // IETF protocol assignments
+		"198.18.0.1":      true, // benchmarking
+		"fe80::1":         true, // link local IPv6
+		"::ffff:10.0.0.1": true, // IPv4-mapped IPv6 private address
+		"8.8.8.8":         false,
+	}
+
+	for addr, isPrivate := range testData {
+		if got := Default().Contains(net.ParseIP(addr)); got != isPrivate {
+			t.Errorf("%s: expected private=%v, got %v", addr, isPrivate, got)
+		}
+	}
+}
+
+func TestPrivateRangesCustomization(t *testing.T) {
+	corpVPN := "203.0.113.0/24"
+	_, cidr, _ := net.ParseCIDR(corpVPN)
+	ranges := &PrivateRanges{Blocks: []*net.IPNet{cidr}}
+
+	if !ranges.Contains(net.ParseIP("203.0.113.5")) {
+		t.Errorf("expected %s to be contained in custom range %s", "203.0.113.5", corpVPN)
+	}
+	if ranges.Contains(net.ParseIP("8.8.8.8")) {
+		t.Errorf("expected 8.8.8.8 not to be contained in custom range %s", corpVPN)
+	}
+}
@@ -7,46 +7,14 @@ import (
 	"strings"
 )
 
-var cidrs []*net.IPNet
-
-func init() {
-	maxCidrBlocks := []string{
-		"127.0.0.1/8",    // localhost
-		"10.0.0.0/8",     // 24-bit block
-		"172.16.0.0/12",  // 20-bit block
-		"192.168.0.0/16", // 16-bit block
-		"169.254.0.0/16", // link local address
-		"::1/128",        // localhost IPv6
-		"fc00::/7",       // unique local address IPv6
-		"fe80::/10",      // link local address IPv6
-	}
-
-	cidrs = make([]*net.IPNet, len(maxCidrBlocks))
-	for i, maxCidrBlock := range maxCidrBlocks {
-		_, cidr, _ := net.ParseCIDR(maxCidrBlock)
-		cidrs[i] = cidr
-	}
-}
-
-// isLocalAddress works by checking if the address is under private CIDR blocks.
-// List of private CIDR blocks can be seen on :
-//
-// https://en.wikipedia.org/wiki/Private_network
-//
-// https://en.wikipedia.org/wiki/Link-local_address
+// isPrivateAddress reports whether address falls inside DefaultPrivateRanges.
 func isPrivateAddress(address string) (bool, error) {
 	ipAddress := net.ParseIP(address)
 	if ipAddress == nil {
 		return false, errors.New("address is not valid")
 	}
 
-	for i := range cidrs {
-		if cidrs[i].Contains(ipAddress) {
-			return true, nil
-		}
-	}
-
-	return false, nil
+	return DefaultPrivateRanges.Contains(ipAddress), nil
 }
 
 func getIPfromHostPort(hostPort string) string {
@@ -64,42 +32,83 @@ func getIPfromHostPort(hostPort string) string {
 	return remoteIP
 }
 
+// headerValues joins all occurrences of header into a single comma-separated
+// value. A proxy chain may legally emit a header multiple times (RFC 7230
+// §3.2.2) rather than once with a comma-joined value, and r.Header.Get only
+// ever returns the first occurrence.
+func headerValues(r *http.Request, header string) string {
+	return strings.Join(r.Header.Values(header), ",")
+}
+
+// candidateAddresses splits header's joined value on commas and returns the
+// address of each entry, dropping entries that don't carry one.
+func candidateAddresses(r *http.Request, header string) []string {
+	parts := strings.Split(headerValues(r, header), ",")
+	addresses := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if addr := getIPfromHostPort(strings.TrimSpace(part)); net.ParseIP(addr) != nil {
+			addresses = append(addresses, addr)
+		}
+	}
+	return addresses
+}
+
 func isValidPublicIP(ip string) bool {
 	isPrivate, err := isPrivateAddress(ip)
 	return (err == nil && !isPrivate)
 }
 
-// ClientIPFromRequest return client's real public IP address from http request headers.
+// ClientIPFromRequest returns the client's real public IP address from http
+// request headers, using a private-IP heuristic rather than a configured
+// trust list: it returns the first address it finds, in header-priority
+// order, that doesn't fall inside DefaultPrivateRanges. Unlike Resolver,
+// this trusts any client willing to set these headers, so it is only
+// suitable when nothing upstream can set them to a spoofed value. For an
+// explicit, spoof-resistant trust boundary, use a Resolver configured with
+// TrustedProxies instead.
 func ClientIPFromRequest(r *http.Request) (ip string, source string) {
 	clientIP := ""
 
-	//TODO:
-	//Check Standard headers
-	//Forwarded: for=192.0.2.60:1234;proto=http;by=203.0.113.43
+	// Try from the standard Forwarded header (RFC 7239) and return the first global address
+	for _, element := range ParseForwarded(headerValues(r, "Forwarded")) {
+		clientIP = ipFromForwardedFor(element.For)
+		if isValidPublicIP(clientIP) {
+			return clientIP, "Forwarded"
+		}
+	}
 
-	//Try from X-Forwarded-For and return the first global address
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
-	for _, address := range strings.Split(xForwardedFor, ",") {
+	// Try from X-Forwarded-For and return the first global address
+	for _, address := range strings.Split(headerValues(r, "X-Forwarded-For"), ",") {
 		clientIP = getIPfromHostPort(address)
 		if isValidPublicIP(clientIP) {
 			return clientIP, "X-Forwarded-For"
 		}
 	}
 
-	//Try from X-Real-Ip
+	// Try from vendor CDN/PaaS headers and return the first global address
+	for _, header := range VendorHeaders {
+		for _, address := range strings.Split(headerValues(r, header), ",") {
+			clientIP = getIPfromHostPort(address)
+			if isValidPublicIP(clientIP) {
+				return clientIP, header
+			}
+		}
+	}
+
+	// Try from X-Real-Ip
 	clientIP = getIPfromHostPort(r.Header.Get("X-Real-Ip"))
 	if isValidPublicIP(clientIP) {
 		return clientIP, "X-Real-Ip"
 	}
 
-	//Try from X-Client-IP
+	// Try from X-Client-IP
 	clientIP = getIPfromHostPort(r.Header.Get("X-Client-Ip"))
 	if isValidPublicIP(clientIP) {
 		return clientIP, "X-Client-Ip"
 	}
 
-	//Final
-	//Try from r.RemoteAddr
+	// Final
+	// Try from r.RemoteAddr
 	clientIP = getIPfromHostPort(r.RemoteAddr)
 	if isValidPublicIP(clientIP) {
 		return clientIP, "remoteAddr"
@@ -108,25 +117,20 @@ func ClientIPFromRequest(r *http.Request) (ip string, source string) {
 	return "", ""
 }
 
-// FromRequest return client's real public IP address from http request headers.
+// FromRequest returns the client's real IP address from http request headers.
+// It returns the first X-Forwarded-For address that doesn't fall inside
+// DefaultPrivateRanges, otherwise the X-Real-Ip value as is, without a
+// private-range check; when both headers are empty it returns the IP of
+// r.RemoteAddr. Unlike ClientIPFromRequest, it does not consult the
+// Forwarded, vendor or X-Client-Ip headers.
 func FromRequest(r *http.Request) string {
 	// Fetch header value
 	xRealIP := r.Header.Get("X-Real-Ip")
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
+	xForwardedFor := headerValues(r, "X-Forwarded-For")
 
 	// If both empty, return IP from remote address
 	if xRealIP == "" && xForwardedFor == "" {
-		var remoteIP string
-
-		// If there are colon in remote address, remove the port number
-		// otherwise, return remote address as is
-		if strings.ContainsRune(r.RemoteAddr, ':') {
-			remoteIP, _, _ = net.SplitHostPort(r.RemoteAddr)
-		} else {
-			remoteIP = r.RemoteAddr
-		}
-
-		return remoteIP
+		return getIPfromHostPort(r.RemoteAddr)
 	}
 
 	// Check list of IP in X-Forwarded-For and return the first global address
@@ -155,3 +155,27 @@ func TestClientIPFromRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestClientIPFromRequestMultipleXFFHeaderLines(t *testing.T) {
+	// A proxy chain may legally emit X-Forwarded-For as separate header
+	// lines instead of one comma-joined value (RFC 7230 §3.2.2). The first
+	// line holds only a private address and the public address is on the
+	// second, so reading just the first line (as Header.Get does) finds no
+	// public address; only reading both does.
+	privateAddr := "127.0.0.1"
+	publicAddr := "144.12.54.87"
+
+	headerList := http.Header{}
+	headerList.Add("X-Forwarded-For", privateAddr)
+	headerList.Add("X-Forwarded-For", publicAddr)
+
+	request := &http.Request{RemoteAddr: "", Header: headerList}
+
+	actual, source := ClientIPFromRequest(request)
+	if actual != publicAddr {
+		t.Errorf("expected %s, got %s", publicAddr, actual)
+	}
+	if source != "X-Forwarded-For" {
+		t.Errorf("expected source X-Forwarded-For, got %s", source)
+	}
+}
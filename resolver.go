@@ -0,0 +1,159 @@
+package realip
+
+import (
+	"net"
+	"net/http"
+)
+
+// defaultListHeaders is the set of headers a Resolver walks when none are
+// configured via WithTrustedHeaders.
+var defaultListHeaders = []string{"X-Forwarded-For"}
+
+// Resolver resolves the client IP of an incoming request by walking a
+// forwarding header from right to left (the order proxies append to it)
+// and skipping any hop that falls inside TrustedProxies, per MDN's
+// recommendation for parsing X-Forwarded-For safely. Unlike the private-IP
+// heuristic used by FromRequest and ClientIPFromRequest, trust here is
+// explicit: callers must configure which proxies are allowed to set these
+// headers, otherwise any client can spoof them.
+type Resolver struct {
+	// TrustedProxies lists the CIDR blocks of proxies allowed to set
+	// TrustedHeaders. A hop whose address falls inside one of these blocks
+	// is skipped when walking a header's address list.
+	TrustedProxies []*net.IPNet
+
+	// TrustedHeaders lists, in priority order, the headers to consult for
+	// the client address. Defaults to []string{"X-Forwarded-For"}.
+	TrustedHeaders []string
+
+	// VendorHeaders lists, in priority order, CDN/PaaS headers to consult
+	// before TrustedHeaders. They are only honored when the request's
+	// direct peer (r.RemoteAddr) falls inside TrustedProxies, since there
+	// is no list to walk past a spoofed value. Defaults to the
+	// package-level VendorHeaders.
+	VendorHeaders []string
+}
+
+// Option configures a Resolver constructed by New.
+type Option func(*Resolver)
+
+// WithTrustedProxies adds CIDR blocks to the Resolver's trusted-proxy set.
+// Entries that fail to parse as CIDRs are ignored.
+func WithTrustedProxies(cidrs ...string) Option {
+	return func(res *Resolver) {
+		for _, cidr := range cidrs {
+			if _, block, err := net.ParseCIDR(cidr); err == nil {
+				res.TrustedProxies = append(res.TrustedProxies, block)
+			}
+		}
+	}
+}
+
+// WithTrustedHeaders overrides the headers a Resolver consults for the
+// client address, in priority order.
+func WithTrustedHeaders(headers ...string) Option {
+	return func(res *Resolver) {
+		res.TrustedHeaders = append(res.TrustedHeaders, headers...)
+	}
+}
+
+// WithVendorHeaders overrides the single-valued CDN/PaaS headers a Resolver
+// consults, in priority order.
+func WithVendorHeaders(headers ...string) Option {
+	return func(res *Resolver) {
+		res.VendorHeaders = append(res.VendorHeaders, headers...)
+	}
+}
+
+// New builds a Resolver from the given options. With no options, the
+// returned Resolver has an empty trust list, so ClientIP falls back to
+// r.RemoteAddr for every request.
+func New(opts ...Option) *Resolver {
+	res := &Resolver{}
+	for _, opt := range opts {
+		opt(res)
+	}
+	return res
+}
+
+// ClientIP returns the request's client IP and the name of the header it
+// was taken from. With no TrustedProxies configured, or if the request's
+// direct peer (r.RemoteAddr) isn't itself one, nothing can distinguish a
+// real hop from a spoofed one, so ClientIP doesn't consult any header at
+// all and returns r.RemoteAddr outright — the MDN-recommended right-to-left
+// walk only makes sense once at least the immediate connection is trusted.
+// Otherwise it first checks res.VendorHeaders for a CDN/PaaS-supplied
+// address, then the standard Forwarded header (RFC 7239), then each of
+// res.TrustedHeaders in turn — scanning the resulting addresses from right
+// to left and skipping any that fall inside res.TrustedProxies. The first
+// untrusted hop is returned. If every hop is trusted or a header is empty,
+// ClientIP falls back to r.RemoteAddr.
+func (res *Resolver) ClientIP(r *http.Request) (ip string, source string) {
+	if len(res.TrustedProxies) == 0 || !res.peerIsTrustedProxy(r) {
+		return getIPfromHostPort(r.RemoteAddr), "remoteAddr"
+	}
+
+	vendorHeaders := res.VendorHeaders
+	if len(vendorHeaders) == 0 {
+		vendorHeaders = VendorHeaders
+	}
+
+	for _, header := range vendorHeaders {
+		if addresses := candidateAddresses(r, header); len(addresses) > 0 {
+			return addresses[0], header
+		}
+	}
+
+	if addr, ok := res.firstUntrustedHop(forwardedAddresses(r)); ok {
+		return addr, "Forwarded"
+	}
+
+	headers := res.TrustedHeaders
+	if len(headers) == 0 {
+		headers = defaultListHeaders
+	}
+
+	for _, header := range headers {
+		if addr, ok := res.firstUntrustedHop(candidateAddresses(r, header)); ok {
+			return addr, header
+		}
+	}
+
+	return getIPfromHostPort(r.RemoteAddr), "remoteAddr"
+}
+
+// firstUntrustedHop scans addresses from right to left — the order proxies
+// append to a forwarding header — and returns the first one that doesn't
+// fall inside res.TrustedProxies.
+func (res *Resolver) firstUntrustedHop(addresses []string) (addr string, ok bool) {
+	for i := len(addresses) - 1; i >= 0; i-- {
+		if res.isTrustedProxy(addresses[i]) {
+			continue
+		}
+		return addresses[i], true
+	}
+	return "", false
+}
+
+// isTrustedProxy reports whether addr falls inside one of res.TrustedProxies.
+func (res *Resolver) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, block := range res.TrustedProxies {
+		if block.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// peerIsTrustedProxy reports whether the request's direct peer falls inside
+// res.TrustedProxies.
+func (res *Resolver) peerIsTrustedProxy(r *http.Request) bool {
+	addr := getIPfromHostPort(r.RemoteAddr)
+	return addr != "" && res.isTrustedProxy(addr)
+}
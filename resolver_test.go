@@ -0,0 +1,119 @@
+package realip
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResolverClientIP(t *testing.T) {
+	newRequest := func(remoteAddr, xForwardedFor string) *http.Request {
+		h := http.Header{}
+		if xForwardedFor != "" {
+			h.Set("X-Forwarded-For", xForwardedFor)
+		}
+		return &http.Request{RemoteAddr: remoteAddr, Header: h}
+	}
+
+	publicAddr := "144.12.54.87"
+	proxyAddr := "10.0.0.5"
+	directPeer := "203.0.113.9"
+
+	testData := []struct {
+		name           string
+		resolver       *Resolver
+		request        *http.Request
+		expectedIP     string
+		expectedSource string
+	}{
+		{
+			// With nothing configured as trusted, there is no way to tell a
+			// real proxy hop from a client spoofing the header, so the
+			// header is never consulted at all.
+			name:           "No trusted proxies ignores headers and falls back to RemoteAddr",
+			resolver:       New(),
+			request:        newRequest(directPeer, "198.51.100.9, "+publicAddr),
+			expectedIP:     directPeer,
+			expectedSource: "remoteAddr",
+		}, {
+			name:           "Skips trusted proxy hop from the right",
+			resolver:       New(WithTrustedProxies("10.0.0.0/8")),
+			request:        newRequest(proxyAddr, publicAddr+", "+proxyAddr),
+			expectedIP:     publicAddr,
+			expectedSource: "X-Forwarded-For",
+		}, {
+			name:           "All hops trusted falls back to RemoteAddr",
+			resolver:       New(WithTrustedProxies("10.0.0.0/8")),
+			request:        newRequest(proxyAddr, proxyAddr+", "+proxyAddr),
+			expectedIP:     proxyAddr,
+			expectedSource: "remoteAddr",
+		}, {
+			name:           "No header falls back to RemoteAddr",
+			resolver:       New(WithTrustedProxies("10.0.0.0/8")),
+			request:        newRequest(publicAddr, ""),
+			expectedIP:     publicAddr,
+			expectedSource: "remoteAddr",
+		}, {
+			// The direct peer isn't a configured trusted proxy, so the
+			// header is attacker-controlled end to end and must be ignored,
+			// even though TrustedProxies is non-empty.
+			name:           "Untrusted direct peer ignores header despite configured trusted proxies",
+			resolver:       New(WithTrustedProxies("10.0.0.0/8")),
+			request:        newRequest(directPeer, "9.9.9.9"),
+			expectedIP:     directPeer,
+			expectedSource: "remoteAddr",
+		},
+	}
+
+	for _, v := range testData {
+		ip, source := v.resolver.ClientIP(v.request)
+		if ip != v.expectedIP || source != v.expectedSource {
+			t.Errorf("%s: expected [%s]/[%s] but got [%s]/[%s]", v.name, v.expectedIP, v.expectedSource, ip, source)
+		}
+	}
+}
+
+func TestResolverClientIPForwarded(t *testing.T) {
+	proxyAddr := "10.0.0.5"
+	publicAddr := "144.12.54.87"
+
+	h := http.Header{}
+	h.Set("Forwarded", "for="+publicAddr+", for="+proxyAddr)
+
+	request := &http.Request{RemoteAddr: proxyAddr, Header: h}
+
+	resolver := New(WithTrustedProxies("10.0.0.0/8"))
+	ip, source := resolver.ClientIP(request)
+	if ip != publicAddr {
+		t.Errorf("expected %s, got %s", publicAddr, ip)
+	}
+	if source != "Forwarded" {
+		t.Errorf("expected source Forwarded, got %s", source)
+	}
+}
+
+func TestResolverClientIPMultipleXFFHeaderLines(t *testing.T) {
+	// A proxy chain may legally emit X-Forwarded-For as separate header
+	// lines instead of one comma-joined value (RFC 7230 §3.2.2). The
+	// trusted hop is deliberately the first line and the public address
+	// only appears on the second, so a Header.Get-only implementation
+	// (which sees just the first line, finds nothing but a trusted hop,
+	// and falls back to RemoteAddr) would fail this test; only joining
+	// both lines via Header.Values reaches the public address.
+	proxyAddr := "10.0.0.6"
+	publicAddr := "144.12.54.87"
+
+	h := http.Header{}
+	h.Add("X-Forwarded-For", proxyAddr)
+	h.Add("X-Forwarded-For", publicAddr)
+
+	request := &http.Request{RemoteAddr: proxyAddr, Header: h}
+
+	resolver := New(WithTrustedProxies("10.0.0.0/8"))
+	ip, source := resolver.ClientIP(request)
+	if ip != publicAddr {
+		t.Errorf("expected %s, got %s", publicAddr, ip)
+	}
+	if source != "X-Forwarded-For" {
+		t.Errorf("expected source X-Forwarded-For, got %s", source)
+	}
+}
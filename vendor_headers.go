@@ -0,0 +1,17 @@
+package realip
+
+// VendorHeaders is the ordered list of headers set by CDN and PaaS
+// providers to carry the client IP. Each one normally holds a single
+// address, though like X-Forwarded-For it may legally be sent as several
+// header lines or a comma-joined value; the first candidate address found
+// is used. ClientIPFromRequest consults these using the same private-IP
+// heuristic as its other headers; Resolver.ClientIP only consults them when
+// the request's direct peer is itself a trusted proxy.
+var VendorHeaders = []string{
+	"CF-Connecting-IP",    // Cloudflare
+	"True-Client-IP",      // Akamai, Cloudflare Enterprise
+	"Fly-Client-IP",       // Fly.io
+	"Fastly-Client-IP",    // Fastly
+	"X-Azure-ClientIP",    // Azure
+	"X-Appengine-User-IP", // Google App Engine
+}
@@ -0,0 +1,66 @@
+package realip
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIPFromRequestVendorHeader(t *testing.T) {
+	privateAddr := "10.0.0.5:1234"
+	publicAddr := "144.12.54.87"
+
+	h := http.Header{}
+	h.Set("CF-Connecting-IP", publicAddr)
+
+	request := &http.Request{RemoteAddr: privateAddr, Header: h}
+
+	actual, source := ClientIPFromRequest(request)
+	if actual != publicAddr {
+		t.Errorf("expected %s, got %s", publicAddr, actual)
+	}
+	if source != "CF-Connecting-IP" {
+		t.Errorf("expected source CF-Connecting-IP, got %s", source)
+	}
+}
+
+func TestResolverClientIPVendorHeader(t *testing.T) {
+	publicAddr := "144.12.54.87"
+	cdnEdge := "198.51.100.10"
+
+	newRequest := func(remoteAddr, header, value string) *http.Request {
+		h := http.Header{}
+		if header != "" {
+			h.Set(header, value)
+		}
+		return &http.Request{RemoteAddr: remoteAddr, Header: h}
+	}
+
+	testData := []struct {
+		name           string
+		resolver       *Resolver
+		request        *http.Request
+		expectedIP     string
+		expectedSource string
+	}{
+		{
+			name:           "Trusted CDN edge peer honors vendor header",
+			resolver:       New(WithTrustedProxies("198.51.100.0/24")),
+			request:        newRequest(cdnEdge, "CF-Connecting-IP", publicAddr),
+			expectedIP:     publicAddr,
+			expectedSource: "CF-Connecting-IP",
+		}, {
+			name:           "Untrusted peer ignores vendor header",
+			resolver:       New(WithTrustedProxies("198.51.100.0/24")),
+			request:        newRequest(publicAddr, "CF-Connecting-IP", "203.0.113.9"),
+			expectedIP:     publicAddr,
+			expectedSource: "remoteAddr",
+		},
+	}
+
+	for _, v := range testData {
+		ip, source := v.resolver.ClientIP(v.request)
+		if ip != v.expectedIP || source != v.expectedSource {
+			t.Errorf("%s: expected [%s]/[%s] but got [%s]/[%s]", v.name, v.expectedIP, v.expectedSource, ip, source)
+		}
+	}
+}